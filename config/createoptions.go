@@ -0,0 +1,30 @@
+package config
+
+// CreateOptions are the tunables fixed at volume-creation time. A Policy
+// carries a CreateOptions as the defaults applied to every Volume created
+// under it; the Volume then keeps its own copy of whatever values it was
+// actually created with.
+type CreateOptions struct {
+	Size uint64
+	// Backend selects which storage.NamedDriver (e.g. "ceph", "nfs") the
+	// volume is provisioned on.
+	Backend string
+	// NoCloning, when set on a policy, rejects any
+	// /VolumeDriver.Create -o from=... request naming that policy as the
+	// clone source.
+	NoCloning bool
+	// Scope overrides Global.Scope for volumes created under this policy.
+	// Empty means defer to the global default.
+	Scope     string
+	ParamsMap map[string]string
+}
+
+// Params returns the backend driver parameters for this CreateOptions,
+// never nil.
+func (c CreateOptions) Params() map[string]string {
+	if c.ParamsMap == nil {
+		return map[string]string{}
+	}
+
+	return c.ParamsMap
+}
@@ -0,0 +1,32 @@
+package config
+
+import "time"
+
+// Global is the daemon-wide configuration the volmaster serves at
+// GET /global. Every volplugin daemon polls it in DaemonConfig.getGlobal
+// and re-polls it on a timer in DaemonConfig.watchGlobal, so a change to
+// MountPath, Timeout, TTL or Scope on the master reaches running daemons
+// without a restart. Debug is the exception: the request logging and the
+// catch-all debug route are set up from its value when the router is
+// built, and watchGlobal only ever turns debug logging on, never off.
+type Global struct {
+	Debug     bool
+	MountPath string
+	Timeout   time.Duration
+	TTL       time.Duration
+	// Scope is the docker volume plugin Capabilities.Scope ("global" or
+	// "local") volplugin reports by default. A policy can override this
+	// for its own volumes via Policy.CreateOptions.Scope.
+	Scope string
+}
+
+// NewGlobalConfig returns the defaults volplugin fills in for any field the
+// volmaster's /global response leaves out.
+func NewGlobalConfig() *Global {
+	return &Global{
+		MountPath: "/mnt",
+		Timeout:   5 * time.Second,
+		TTL:       5 * time.Second,
+		Scope:     "local",
+	}
+}
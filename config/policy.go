@@ -0,0 +1,7 @@
+package config
+
+// Policy groups the defaults applied to every volume created under it.
+type Policy struct {
+	Name          string
+	CreateOptions CreateOptions
+}
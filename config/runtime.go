@@ -0,0 +1,8 @@
+package config
+
+// RuntimeOptions are the live-tunable, per-volume settings (such as IOPS
+// caps) that can be pushed to an already-mounted volume without
+// recreating it.
+type RuntimeOptions struct {
+	RateIOPS uint64
+}
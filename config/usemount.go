@@ -0,0 +1,11 @@
+package config
+
+import "github.com/contiv/volplugin/lock"
+
+// UseMount records which host (or lock.Unlocked) currently holds a volume
+// mounted, and why.
+type UseMount struct {
+	Volume   string
+	Reason   lock.Reason
+	Hostname string
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"time"
+
+	"github.com/contiv/volplugin/storage"
+)
+
+// Volume is a single provisioned volume as recorded by the volmaster.
+type Volume struct {
+	PolicyName string
+	VolumeName string
+	// Backend selects which storage.NamedDriver this volume was
+	// provisioned on; it is fixed at creation time and is independent of
+	// whatever the owning policy's current default is.
+	Backend string
+	// Unlocked volumes may be mounted from any host without acquiring the
+	// per-host UseMount lock.
+	Unlocked      bool
+	CreateOptions CreateOptions
+	DriverOptions storage.DriverOptions
+	LastUsed      time.Time
+}
@@ -112,6 +112,48 @@ func (s *systemtestSuite) TestVolpluginHostLabel(c *C) {
 	c.Assert(ut.Hostname, Equals, "quux")
 }
 
+func (s *systemtestSuite) TestVolpluginMountRefcount(c *C) {
+	c.Assert(s.createVolume("mon0", "policy1", "test", nil), IsNil)
+
+	out1, err := s.docker("run -itd -v policy1/test:/mnt alpine sleep 10m")
+	c.Assert(err, IsNil)
+	defer s.docker("rm -f " + out1)
+
+	out2, err := s.docker("run -itd -v policy1/test:/mnt alpine sleep 10m")
+	c.Assert(err, IsNil)
+	defer s.docker("rm -f " + out2)
+
+	_, err = s.docker("stop " + out1)
+	c.Assert(err, IsNil)
+
+	c.Assert(s.vagrant.GetNode("mon0").RunCommand("sudo rbd showmapped | grep -q policy1.test"), IsNil)
+
+	_, err = s.docker("stop " + out2)
+	c.Assert(err, IsNil)
+}
+
+func (s *systemtestSuite) TestVolpluginMountFailureNoOrphanUnmount(c *C) {
+	c.Assert(s.createVolume("mon0", "policy1", "test", nil), IsNil)
+
+	// pre-map the rbd device outside of volplugin so the backend Mount call
+	// volplugin makes on mon0 fails partway through.
+	c.Assert(s.vagrant.GetNode("mon0").RunCommand("sudo rbd map rbd/policy1.test"), IsNil)
+	defer s.vagrant.GetNode("mon0").RunCommand("sudo rbd unmap /dev/rbd/rbd/policy1.test")
+
+	_, err := s.docker("run -itd -v policy1/test:/mnt alpine sleep 10m")
+	c.Assert(err, NotNil)
+
+	c.Assert(s.vagrant.GetNode("mon0").RunCommand("sudo rbd unmap /dev/rbd/rbd/policy1.test"), IsNil)
+
+	// the failed mount must not have left the volume's lock or refcount
+	// held, so a second mount on another host should succeed immediately
+	// without waiting for TTL expiry.
+	c.Assert(s.createVolume("mon1", "policy1", "test", nil), IsNil)
+	out, err := s.vagrant.GetNode("mon1").RunCommandWithOutput("docker run -itd -v policy1/test:/mnt alpine sleep 10m")
+	c.Assert(err, IsNil)
+	defer s.vagrant.GetNode("mon1").RunCommand("docker rm -f " + strings.TrimSpace(out))
+}
+
 func (s *systemtestSuite) TestVolpluginMountPath(c *C) {
 	c.Assert(s.uploadGlobal("mountpath_global"), IsNil)
 	time.Sleep(1 * time.Second)
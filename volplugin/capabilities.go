@@ -0,0 +1,81 @@
+package volplugin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/contiv/volplugin/config"
+)
+
+// Scope values for CapabilitiesResponse, matching the docker volume plugin
+// protocol: https://docs.docker.com/engine/extend/plugins_volume/#volumedrivercapabilities
+const (
+	scopeGlobal = "global"
+	scopeLocal  = "local"
+)
+
+// Capability describes the scope a volume driver operates in. Docker (and
+// Swarm's scheduler) uses this to decide whether a volume created through
+// this driver can be shared across hosts (global) or is pinned to the host
+// that created it (local).
+type Capability struct {
+	Scope string
+}
+
+// CapabilitiesResponse is taken from
+// https://docs.docker.com/engine/extend/plugins_volume/#volumedrivercapabilities
+type CapabilitiesResponse struct {
+	Capabilities Capability
+}
+
+// capabilities reports the scope of the driver as configured globally. The
+// docker plugin protocol calls this once at activation time with no volume
+// context, so it can only ever report the global default; it cannot itself
+// answer "what scope does this one volume have". That per-volume answer is
+// what volumeScope and scopeForPolicy below are for - see their use in
+// create.go and mount.go.
+func (dc *DaemonConfig) capabilities(w http.ResponseWriter, r *http.Request) {
+	content, err := json.Marshal(&CapabilitiesResponse{
+		Capabilities: Capability{Scope: dc.Global.Scope},
+	})
+
+	if err != nil {
+		log.Errorf("Could not marshal capabilities response: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(content)
+}
+
+// scopeForPolicy resolves the effective scope for a policy: the policy's
+// own override if it set one, otherwise the global default.
+func (dc *DaemonConfig) scopeForPolicy(policyName string) string {
+	policy, err := dc.Client.GetPolicy(policyName)
+	if err != nil {
+		log.Warnf("Could not resolve policy %q while computing scope, falling back to global default: %v", policyName, err)
+		return dc.Global.Scope
+	}
+
+	if policy.CreateOptions.Scope == "" {
+		return dc.Global.Scope
+	}
+
+	return policy.CreateOptions.Scope
+}
+
+// volumeScope resolves the effective scope for an already-created volume.
+// A plain create persists the scope it resolved at creation time into the
+// volume's own params (see create.go), so that the answer for an existing
+// volume doesn't drift if the policy's default is changed later; that's
+// consulted first. Volumes cloned from a snapshot get no scope recorded
+// this way, so for them, as for volumes created before this existed,
+// scopeForPolicy is the fallback.
+func (dc *DaemonConfig) volumeScope(policyName string, vol *config.Volume) string {
+	if scope := vol.CreateOptions.Params()["scope"]; scope != "" {
+		return scope
+	}
+
+	return dc.scopeForPolicy(policyName)
+}
@@ -0,0 +1,141 @@
+package volplugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/contiv/errored"
+	"github.com/contiv/volplugin/storage/backend"
+)
+
+// create handles /VolumeDriver.Create. Normally this just asks the
+// volmaster to record a new volume; however docker's plugin protocol has no
+// notion of snapshots, so `docker volume create -o from=policy/vol@snap`
+// is smuggled through here as a regular create whose Opts carry the clone
+// source, and `-o snapshot=name` takes an immediate snapshot of the newly
+// created volume.
+func (dc *DaemonConfig) create(w http.ResponseWriter, r *http.Request) {
+	var req VolumeRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		mountError(w, "Reading create request", err)
+		return
+	}
+
+	parts := strings.SplitN(req.Name, "/", 2)
+	if len(parts) != 2 {
+		mountError(w, "Parsing volume name", errVolumeNotFound)
+		return
+	}
+
+	if from, ok := req.Opts["from"]; ok {
+		if err := dc.createFromSnapshot(parts[0], parts[1], from); err != nil {
+			mountError(w, "Cloning volume from snapshot", err)
+			return
+		}
+	} else {
+		if req.Opts == nil {
+			req.Opts = map[string]string{}
+		}
+
+		// Record the effective scope (policy override, falling back to the
+		// global default) on the volume itself, so that a future Mount for
+		// it - possibly handled by a different volplugin host entirely -
+		// can tell whether it's allowed to run unlocked across hosts
+		// without needing to re-resolve the policy from scratch.
+		if _, ok := req.Opts["scope"]; !ok {
+			req.Opts["scope"] = dc.scopeForPolicy(parts[0])
+		}
+
+		if err := dc.Client.CreateVolume(parts[0], parts[1], req.Opts); err != nil {
+			mountError(w, "Creating volume", err)
+			return
+		}
+	}
+
+	if name, ok := req.Opts["snapshot"]; ok {
+		vol, err := dc.requestVolume(parts[0], parts[1])
+		if err != nil {
+			mountError(w, "Requesting volume after create", err)
+			return
+		}
+
+		driver, err := backend.NewSnapshotDriver(vol.Backend)
+		if err != nil {
+			mountError(w, "Initializing snapshot driver", err)
+			return
+		}
+
+		if err := driver.CreateSnapshot(name, vol.DriverOptions); err != nil {
+			mountError(w, "Snapshotting newly created volume", err)
+			return
+		}
+	}
+
+	content, err := json.Marshal(&VolumeResponse{})
+	if err != nil {
+		mountError(w, "Marshalling create response", err)
+		return
+	}
+
+	w.Write(content)
+}
+
+// createFromSnapshot clones "policy/volume@snapshot" into a new volume
+// named policy/name, rejecting the request if the source policy disallows
+// cloning or if the destination policy lives on a different backend than
+// the snapshot (the storage backends have no way to clone across pools).
+func (dc *DaemonConfig) createFromSnapshot(policy, name, from string) error {
+	policyPart, rest := splitOnce(from, "/")
+	volPart, snapPart := splitOnce(rest, "@")
+	if policyPart == "" || volPart == "" || snapPart == "" {
+		return errored.Errorf("Invalid snapshot source %q: expected policy/volume@snapshot", from)
+	}
+
+	srcPolicy, err := dc.Client.GetPolicy(policyPart)
+	if err != nil {
+		return err
+	}
+
+	if srcPolicy.CreateOptions.NoCloning {
+		return errored.Errorf("Policy %q does not allow cloning from snapshots", policyPart)
+	}
+
+	srcVol, err := dc.requestVolume(policyPart, volPart)
+	if err != nil {
+		return err
+	}
+
+	destPolicy, err := dc.Client.GetPolicy(policy)
+	if err != nil {
+		return err
+	}
+
+	if destPolicy.CreateOptions.Backend != srcVol.Backend {
+		return errored.Errorf("Cannot clone %q into policy %q: source is backed by %q, destination policy is backed by %q", from, policy, srcVol.Backend, destPolicy.CreateOptions.Backend)
+	}
+
+	driver, err := backend.NewSnapshotDriver(srcVol.Backend)
+	if err != nil {
+		return err
+	}
+
+	// CopySnapshot has to run against the source volume's driver options to
+	// locate the snapshot, but the clone it produces belongs to the
+	// destination policy from here on: it is recorded with destPolicy's
+	// params, not those of the source volume it was cloned from.
+	if err := driver.CopySnapshot(srcVol.DriverOptions, snapPart, name); err != nil {
+		return err
+	}
+
+	return dc.Client.CreateVolume(policy, name, destPolicy.CreateOptions.Params())
+}
+
+func splitOnce(s, sep string) (string, string) {
+	idx := strings.Index(s, sep)
+	if idx < 0 {
+		return s, ""
+	}
+	return s[:idx], s[idx+len(sep):]
+}
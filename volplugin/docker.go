@@ -0,0 +1,40 @@
+package volplugin
+
+import (
+	dockerclient "github.com/docker/engine-api/client"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
+	"golang.org/x/net/context"
+)
+
+// dockerSocket is the local docker daemon's API socket. It is not the
+// socket docker talks to this plugin over; that one is volplugin.sock
+// under basePath.
+const dockerSocket = "unix:///var/run/docker.sock"
+
+// containerIDsForVolume asks the local docker daemon which running
+// containers currently have volumeName attached, independent of whatever
+// volplugin's own mount bookkeeping believes. It is used both to recover
+// real refcounts after a volplugin restart (updateMounts) and to make sure
+// prune doesn't destroy a volume a container still has mounted.
+func containerIDsForVolume(volumeName string) ([]string, error) {
+	cli, err := dockerclient.NewClient(dockerSocket, "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	f := filters.NewArgs()
+	f.Add("volume", volumeName)
+
+	containers, err := cli.ContainerList(context.Background(), types.ContainerListOptions{Filter: f})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(containers))
+	for _, ctr := range containers {
+		ids = append(ids, ctr.ID)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,254 @@
+package volplugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/contiv/errored"
+	"github.com/contiv/volplugin/config"
+	"github.com/contiv/volplugin/lock"
+	"github.com/contiv/volplugin/storage/backend"
+)
+
+// mount handles /VolumeDriver.Mount. Docker sends one of these per container
+// attaching the volume, distinguished by req.ID. Only the first mount for a
+// given volume name actually talks to the storage backend; subsequent
+// mounts from other containers just join the refcount so the backend is
+// unmounted only when the last consumer releases it.
+//
+// The first mount is a two-phase operation: the volume is reserved in
+// pendingMounts before the backend is touched, and only promoted into
+// mountCount/mountPaths once MountDriver.Mount actually succeeds. This
+// keeps a failed backend mount from ever being tracked as a live mount, so
+// the container teardown that follows a failed mount can't trigger an
+// Unmount against a volume the backend never mounted.
+func (dc *DaemonConfig) mount(w http.ResponseWriter, r *http.Request) {
+	var req VolumeRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		mountError(w, "Reading mount request", err)
+		return
+	}
+
+	parts := strings.SplitN(req.Name, "/", 2)
+	if len(parts) != 2 {
+		mountError(w, "Parsing volume name", errVolumeNotFound)
+		return
+	}
+
+	switch path, state := dc.reserveMount(req.Name, req.ID); state {
+	case mountReserveAlreadyMounted:
+		writeMountResponse(w, path)
+		return
+	case mountReserveAlreadyPending:
+		mountError(w, "Mounting volume", errored.Errorf("Mount of %q already in progress", req.Name))
+		return
+	}
+
+	vol, err := dc.requestVolume(parts[0], parts[1])
+	if err != nil {
+		dc.abandonPendingMount(req.Name)
+		mountError(w, "Requesting volume", err)
+		return
+	}
+
+	payload := &config.UseMount{
+		Volume:   req.Name,
+		Reason:   lock.ReasonMount,
+		Hostname: dc.Host,
+	}
+
+	if vol.Unlocked || dc.volumeScope(parts[0], vol) == scopeGlobal {
+		// A "global"-scoped volume is meant to be mountable from every host
+		// in the cluster at once, the same way an already-Unlocked volume
+		// is: don't pin it to this host's lock.
+		payload.Hostname = lock.Unlocked
+	}
+
+	if err := dc.Client.ReportMount(payload); err != nil {
+		dc.abandonPendingMount(req.Name)
+		mountError(w, "Reporting mount intent", err)
+		return
+	}
+
+	driver, err := backend.NewMountDriver(vol.Backend, dc.Global.MountPath)
+	if err != nil {
+		dc.abandonPendingMount(req.Name)
+		dc.Client.ReportUnmount(payload) // releases the lock ReportMount took above.
+		mountError(w, "Initializing mount driver", err)
+		return
+	}
+
+	mnt, err := driver.Mount(vol.DriverOptions)
+	if err != nil {
+		dc.abandonPendingMount(req.Name)
+		dc.Client.ReportUnmount(payload) // releases the lock ReportMount took above.
+		mountError(w, "Mounting volume", err)
+		return
+	}
+
+	dc.promotePendingMount(req.Name, req.ID, mnt.Path)
+
+	go dc.startRuntimePoll(req.Name, mnt)
+	go dc.Client.HeartbeatMount(dc.Global.TTL, payload, dc.Client.AddStopChan(req.Name))
+
+	writeMountResponse(w, mnt.Path)
+}
+
+// unmount handles /VolumeDriver.Unmount. The backend is only unmounted once
+// the last container ID referencing the volume has released it; a volume
+// that was never successfully mounted (e.g. a prior Mount failed partway)
+// is never passed to the backend's Unmount.
+func (dc *DaemonConfig) unmount(w http.ResponseWriter, r *http.Request) {
+	var req VolumeRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		mountError(w, "Reading unmount request", err)
+		return
+	}
+
+	parts := strings.SplitN(req.Name, "/", 2)
+	if len(parts) != 2 {
+		mountError(w, "Parsing volume name", errVolumeNotFound)
+		return
+	}
+
+	switch dc.releaseMount(req.Name, req.ID) {
+	case mountReleaseNoop, mountReleaseRemaining:
+		writeMountResponse(w, "")
+		return
+	}
+
+	vol, err := dc.requestVolume(parts[0], parts[1])
+	if err != nil {
+		mountError(w, "Requesting volume", err)
+		return
+	}
+
+	driver, err := backend.NewMountDriver(vol.Backend, dc.Global.MountPath)
+	if err != nil {
+		mountError(w, "Initializing mount driver", err)
+		return
+	}
+
+	if err := driver.Unmount(vol.DriverOptions); err != nil {
+		mountError(w, "Unmounting volume", err)
+		return
+	}
+
+	dc.Client.ReportUnmount(&config.UseMount{Volume: req.Name, Hostname: dc.Host})
+
+	writeMountResponse(w, "")
+}
+
+type mountReserveResult int
+
+const (
+	// mountReserveNew means this call is the first one reserving the
+	// volume; the caller should proceed to mount it on the backend.
+	mountReserveNew mountReserveResult = iota
+	// mountReserveAlreadyMounted means the volume is already live; id has
+	// been added to its refcount and the caller can respond immediately.
+	mountReserveAlreadyMounted
+	// mountReserveAlreadyPending means another request is already in the
+	// middle of the first mount for this volume.
+	mountReserveAlreadyPending
+)
+
+// reserveMount reports the state of volumeName's mount and, if this is the
+// first request to see it unmounted, marks it pending so a concurrent
+// request doesn't also invoke the backend.
+func (dc *DaemonConfig) reserveMount(volumeName, id string) (string, mountReserveResult) {
+	dc.mountMutex.Lock()
+	defer dc.mountMutex.Unlock()
+
+	if path, mounted := dc.mountPaths[volumeName]; mounted {
+		dc.mountCount[volumeName][id] = struct{}{}
+		return path, mountReserveAlreadyMounted
+	}
+
+	if _, pending := dc.pendingMounts[volumeName]; pending {
+		return "", mountReserveAlreadyPending
+	}
+
+	dc.pendingMounts[volumeName] = struct{}{}
+	return "", mountReserveNew
+}
+
+// abandonPendingMount clears a failed reservation so a later Mount request
+// for the same volume can retry from scratch.
+func (dc *DaemonConfig) abandonPendingMount(volumeName string) {
+	dc.mountMutex.Lock()
+	delete(dc.pendingMounts, volumeName)
+	dc.mountMutex.Unlock()
+}
+
+// promotePendingMount converts a reservation into a live, tracked mount now
+// that the backend has confirmed it succeeded.
+func (dc *DaemonConfig) promotePendingMount(volumeName, id, path string) {
+	dc.mountMutex.Lock()
+	delete(dc.pendingMounts, volumeName)
+	dc.mountCount[volumeName] = map[string]struct{}{id: {}}
+	dc.mountPaths[volumeName] = path
+	dc.mountMutex.Unlock()
+}
+
+type mountReleaseResult int
+
+const (
+	// mountReleaseNoop means the volume has no tracked mount at all (e.g. a
+	// failed Mount's teardown), so the backend must not be touched. An id
+	// that is unknown for a volume that is tracked yields
+	// mountReleaseRemaining instead.
+	mountReleaseNoop mountReleaseResult = iota
+	// mountReleaseRemaining means other container IDs still hold the volume.
+	mountReleaseRemaining
+	// mountReleaseLast means id was the final reference; the backend should
+	// now be unmounted.
+	mountReleaseLast
+)
+
+// releaseMount removes id from the volume's reference set and reports what
+// the caller should do about the backend as a result.
+func (dc *DaemonConfig) releaseMount(volumeName, id string) mountReleaseResult {
+	dc.mountMutex.Lock()
+	defer dc.mountMutex.Unlock()
+
+	ids, ok := dc.mountCount[volumeName]
+	if !ok {
+		return mountReleaseNoop
+	}
+
+	delete(ids, id)
+
+	if len(ids) > 0 {
+		return mountReleaseRemaining
+	}
+
+	delete(dc.mountCount, volumeName)
+	delete(dc.mountPaths, volumeName)
+	return mountReleaseLast
+}
+
+func writeMountResponse(w http.ResponseWriter, mountPath string) {
+	content, err := json.Marshal(&VolumeResponse{Mountpoint: mountPath})
+	if err != nil {
+		log.Errorf("Marshalling mount response: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(content)
+}
+
+func mountError(w http.ResponseWriter, msg string, err error) {
+	log.Errorf("%s: %v", msg, err)
+	content, mErr := json.Marshal(&VolumeResponse{Err: err.Error()})
+	if mErr != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(content)
+}
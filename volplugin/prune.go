@@ -0,0 +1,148 @@
+package volplugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/contiv/volplugin/config"
+	"github.com/contiv/volplugin/lock"
+	"github.com/contiv/volplugin/storage/backend"
+)
+
+// VolumesPruneReport mirrors docker's own VolumesPruneReport, plus the
+// volume names so operators can see exactly what was reclaimed.
+type VolumesPruneReport struct {
+	VolumesDeleted []string
+	SpaceReclaimed uint64
+}
+
+// prune handles /VolumeDriver.Prune. It is a volplugin extension, not part
+// of the stock docker volume plugin protocol: it is invoked the same way
+// the other VolumeDriver.* endpoints are, via VolumeRequest.Opts, to stay
+// consistent with how this daemon already smuggles extra behavior (like
+// snapshot cloning) through that struct.
+func (dc *DaemonConfig) prune(w http.ResponseWriter, r *http.Request) {
+	var req VolumeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		mountError(w, "Reading prune request", err)
+		return
+	}
+
+	policyFilter := req.Opts["policy"]
+
+	var unusedFor time.Duration
+	if raw, ok := req.Opts["unused-for"]; ok {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			mountError(w, "Parsing unused-for filter", err)
+			return
+		}
+		unusedFor = d
+	}
+
+	volumes, err := dc.Client.ListAllVolumes()
+	if err != nil {
+		mountError(w, "Listing volumes", err)
+		return
+	}
+
+	report := &VolumesPruneReport{VolumesDeleted: []string{}}
+
+	for _, name := range volumes {
+		parts := strings.SplitN(name, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		if policyFilter != "" && parts[0] != policyFilter {
+			continue
+		}
+
+		vol, err := dc.requestVolume(parts[0], parts[1])
+		if err != nil {
+			log.Errorf("Skipping %q during prune: %v", name, err)
+			continue
+		}
+
+		use, err := dc.Client.GetUseMount(name)
+		if err != nil {
+			log.Errorf("Skipping %q during prune: %v", name, err)
+			continue
+		}
+		if use != nil {
+			continue // actively locked for mount or snapshot use
+		}
+
+		inUse, err := volumeInUseByDocker(name)
+		if err != nil {
+			log.Errorf("Skipping %q during prune: %v", name, err)
+			continue
+		}
+		if inUse {
+			continue
+		}
+
+		if unusedFor > 0 && time.Since(vol.LastUsed) < unusedFor {
+			continue
+		}
+
+		if err := dc.destroyVolume(parts[0], parts[1], vol); err != nil {
+			log.Errorf("Could not prune %q: %v", name, err)
+			continue
+		}
+
+		report.VolumesDeleted = append(report.VolumesDeleted, name)
+		report.SpaceReclaimed += vol.CreateOptions.Size
+	}
+
+	content, err := json.Marshal(report)
+	if err != nil {
+		mountError(w, "Marshalling prune report", err)
+		return
+	}
+
+	w.Write(content)
+}
+
+// destroyVolume acquires the remove lock on the master, destroys the
+// backing storage, and releases the lock. Errors from unlocking are logged
+// rather than returned since the volume has already been destroyed.
+func (dc *DaemonConfig) destroyVolume(policy, name string, vol *config.Volume) error {
+	if err := dc.Client.Lock(policy, name, lock.ReasonRemove); err != nil {
+		return err
+	}
+	defer func() {
+		if err := dc.Client.Unlock(policy, name, lock.ReasonRemove); err != nil {
+			log.Errorf("Could not release remove lock on %s/%s: %v", policy, name, err)
+		}
+	}()
+
+	driver, err := backend.NewCRUDDriver(vol.Backend)
+	if err != nil {
+		return err
+	}
+
+	if err := driver.Destroy(vol.DriverOptions); err != nil {
+		return err
+	}
+
+	return dc.Client.RemoveVolume(policy, name)
+}
+
+// volumeInUseByDocker cross-checks the local docker daemon for any
+// container still referencing the volume, independent of volplugin's own
+// mount refcounts, so a volume a container has mounted that volplugin's
+// own in-memory state doesn't (yet) reflect is never pruned out from under
+// it. It only sees containers on this host; the GetUseMount check above it
+// is what catches the volume being locked for use on a different node.
+func volumeInUseByDocker(name string) (bool, error) {
+	ids, err := containerIDsForVolume(name)
+	if err != nil {
+		return false, err
+	}
+
+	return len(ids) > 0, nil
+}
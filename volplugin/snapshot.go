@@ -0,0 +1,128 @@
+package volplugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/contiv/volplugin/storage"
+	"github.com/contiv/volplugin/storage/backend"
+)
+
+// SnapshotRequest carries the arguments for the /VolumeDriver.Snapshot*
+// endpoints. These are volplugin extensions to the docker volume plugin
+// protocol, not part of it, so unlike VolumeRequest they are free to add
+// fields without worrying about breaking docker's own (un)marshalling.
+type SnapshotRequest struct {
+	Name     string
+	Snapshot string
+	// Target is only used by SnapshotCopy: the name of the volume to create
+	// from Snapshot.
+	Target string
+}
+
+// SnapshotListResponse reports the snapshots known for a volume.
+type SnapshotListResponse struct {
+	Snapshots []string
+	Err       string
+}
+
+func (dc *DaemonConfig) snapshotDriverFor(policy, name string) (storage.SnapshotDriver, *storage.DriverOptions, error) {
+	vol, err := dc.requestVolume(policy, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	driver, err := backend.NewSnapshotDriver(vol.Backend)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return driver, &vol.DriverOptions, nil
+}
+
+// snapshotCreate handles /VolumeDriver.SnapshotCreate.
+func (dc *DaemonConfig) snapshotCreate(w http.ResponseWriter, r *http.Request) {
+	var req SnapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		mountError(w, "Reading snapshot create request", err)
+		return
+	}
+
+	parts := strings.SplitN(req.Name, "/", 2)
+	if len(parts) != 2 {
+		mountError(w, "Parsing volume name", errVolumeNotFound)
+		return
+	}
+
+	driver, opts, err := dc.snapshotDriverFor(parts[0], parts[1])
+	if err != nil {
+		mountError(w, "Resolving snapshot driver", err)
+		return
+	}
+
+	if err := driver.CreateSnapshot(req.Snapshot, *opts); err != nil {
+		mountError(w, "Creating snapshot", err)
+		return
+	}
+
+	w.Write([]byte("{}"))
+}
+
+// snapshotList handles /VolumeDriver.SnapshotList.
+func (dc *DaemonConfig) snapshotList(w http.ResponseWriter, r *http.Request) {
+	var req SnapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		mountError(w, "Reading snapshot list request", err)
+		return
+	}
+
+	parts := strings.SplitN(req.Name, "/", 2)
+	if len(parts) != 2 {
+		mountError(w, "Parsing volume name", errVolumeNotFound)
+		return
+	}
+
+	driver, opts, err := dc.snapshotDriverFor(parts[0], parts[1])
+	if err != nil {
+		mountError(w, "Resolving snapshot driver", err)
+		return
+	}
+
+	snapshots, err := driver.ListSnapshots(*opts)
+	if err != nil {
+		mountError(w, "Listing snapshots", err)
+		return
+	}
+
+	content, err := json.Marshal(&SnapshotListResponse{Snapshots: snapshots})
+	if err != nil {
+		mountError(w, "Marshalling snapshot list response", err)
+		return
+	}
+
+	w.Write(content)
+}
+
+// snapshotCopy handles /VolumeDriver.SnapshotCopy, cloning a snapshot into
+// a brand new volume recorded with the volmaster.
+func (dc *DaemonConfig) snapshotCopy(w http.ResponseWriter, r *http.Request) {
+	var req SnapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		mountError(w, "Reading snapshot copy request", err)
+		return
+	}
+
+	parts := strings.SplitN(req.Name, "/", 2)
+	if len(parts) != 2 {
+		mountError(w, "Parsing volume name", errVolumeNotFound)
+		return
+	}
+
+	if err := dc.createFromSnapshot(parts[0], req.Target, req.Name+"@"+req.Snapshot); err != nil {
+		mountError(w, "Copying snapshot", err)
+		return
+	}
+
+	w.Write([]byte("{}"))
+}
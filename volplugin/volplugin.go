@@ -20,6 +20,7 @@ import (
 	"github.com/contiv/volplugin/info"
 	"github.com/contiv/volplugin/lock"
 	"github.com/contiv/volplugin/lock/client"
+	"github.com/contiv/volplugin/storage"
 	"github.com/contiv/volplugin/storage/backend"
 	"github.com/gorilla/mux"
 )
@@ -37,7 +38,9 @@ type DaemonConfig struct {
 	runtimeVolumeMap map[string]config.RuntimeOptions
 	runtimeStopChans map[string]chan struct{}
 	mountMutex       *sync.Mutex
-	mountCount       map[string]int
+	mountCount       map[string]map[string]struct{}
+	mountPaths       map[string]string
+	pendingMounts    map[string]struct{}
 }
 
 // VolumeRequest is taken from
@@ -45,6 +48,9 @@ type DaemonConfig struct {
 type VolumeRequest struct {
 	Name string
 	Opts map[string]string
+	// ID identifies the container performing a Mount or Unmount request. It
+	// is absent from Create/Remove/List/Get/Path requests.
+	ID string
 }
 
 // VolumeResponse is taken from
@@ -87,7 +93,9 @@ func NewDaemonConfig(master, host string) *DaemonConfig {
 		runtimeVolumeMap: map[string]config.RuntimeOptions{},
 		runtimeStopChans: map[string]chan struct{}{},
 		mountMutex:       new(sync.Mutex),
-		mountCount:       map[string]int{},
+		mountCount:       map[string]map[string]struct{}{},
+		mountPaths:       map[string]string{},
+		pendingMounts:    map[string]struct{}{},
 	}
 }
 
@@ -139,15 +147,22 @@ func (dc *DaemonConfig) Daemon() error {
 
 func (dc *DaemonConfig) configureRouter() *mux.Router {
 	var routeMap = map[string]func(http.ResponseWriter, *http.Request){
-		"/Plugin.Activate":      dc.activate,
-		"/Plugin.Deactivate":    dc.nilAction,
-		"/VolumeDriver.Create":  dc.create,
-		"/VolumeDriver.Remove":  dc.getPath, // we never actually remove through docker's interface.
-		"/VolumeDriver.List":    dc.list,
-		"/VolumeDriver.Get":     dc.get,
-		"/VolumeDriver.Path":    dc.getPath,
-		"/VolumeDriver.Mount":   dc.mount,
-		"/VolumeDriver.Unmount": dc.unmount,
+		"/Plugin.Activate":           dc.activate,
+		"/Plugin.Deactivate":         dc.nilAction,
+		"/VolumeDriver.Capabilities": dc.capabilities,
+		"/VolumeDriver.Create":       dc.create,
+		"/VolumeDriver.Remove":       dc.getPath, // we never actually remove through docker's interface.
+		"/VolumeDriver.List":         dc.list,
+		"/VolumeDriver.Get":          dc.get,
+		"/VolumeDriver.Path":         dc.getPath,
+		"/VolumeDriver.Mount":        dc.mount,
+		"/VolumeDriver.Unmount":      dc.unmount,
+
+		"/VolumeDriver.SnapshotCreate": dc.snapshotCreate,
+		"/VolumeDriver.SnapshotList":   dc.snapshotList,
+		"/VolumeDriver.SnapshotCopy":   dc.snapshotCopy,
+
+		"/VolumeDriver.Prune": dc.prune,
 	}
 
 	router := mux.NewRouter()
@@ -266,6 +281,31 @@ func (dc *DaemonConfig) updateMounts() error {
 				}
 			}
 
+			ids, err := containerIDsForVolume(mount.Volume.Name)
+			if err != nil {
+				log.Errorf("Could not list containers using %q via docker: %v; leaving it mounted and retrying on next restart", mount.Volume.Name, err)
+				continue
+			}
+
+			if len(ids) == 0 {
+				log.Infof("No running container references %q after restart; releasing it", mount.Volume.Name)
+				if err := cd.Unmount(storage.DriverOptions{Volume: mount.Volume, Timeout: dc.Global.Timeout}); err != nil {
+					log.Errorf("Could not release unreferenced mount %q: %v", mount.Volume.Name, err)
+				}
+				dc.Client.ReportUnmount(payload)
+				continue
+			}
+
+			idSet := make(map[string]struct{}, len(ids))
+			for _, id := range ids {
+				idSet[id] = struct{}{}
+			}
+
+			dc.mountMutex.Lock()
+			dc.mountCount[mount.Volume.Name] = idSet
+			dc.mountPaths[mount.Volume.Name] = mount.Path
+			dc.mountMutex.Unlock()
+
 			go dc.startRuntimePoll(mount.Volume.Name, mount)
 			go dc.Client.HeartbeatMount(dc.Global.TTL, payload, dc.Client.AddStopChan(mount.Volume.Name))
 		}